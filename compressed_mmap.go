@@ -0,0 +1,270 @@
+package main
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/golang/snappy"
+	lz4 "github.com/pierrec/lz4/v4"
+)
+
+type Codec uint8
+
+const (
+	CodecSnappy Codec = iota
+	CodecLZ4
+)
+
+const (
+	compressedBlockRecords = 64
+	compressedBlockHeader  = 4 + 1 + 4 // uncompressed_len uint32 | codec uint8 | compressed_len uint32
+)
+
+func compress(codec Codec, src []byte) ([]byte, error) {
+	switch codec {
+	case CodecSnappy:
+		return snappy.Encode(nil, src), nil
+	case CodecLZ4:
+		dst := make([]byte, lz4.CompressBlockBound(len(src)))
+		var c lz4.Compressor
+		n, err := c.CompressBlock(src, dst)
+		if err != nil {
+			return nil, err
+		}
+		return dst[:n], nil
+	default:
+		return nil, fmt.Errorf("unknown codec %d", codec)
+	}
+}
+
+func decompress(codec Codec, src []byte, uncompressedLen int) ([]byte, error) {
+	switch codec {
+	case CodecSnappy:
+		return snappy.Decode(nil, src)
+	case CodecLZ4:
+		dst := make([]byte, uncompressedLen)
+		n, err := lz4.UncompressBlock(src, dst)
+		if err != nil {
+			return nil, err
+		}
+		return dst[:n], nil
+	default:
+		return nil, fmt.Errorf("unknown codec %d", codec)
+	}
+}
+
+type CompressedMmapWriter struct {
+	file  *os.File
+	codec Codec
+
+	buf            []byte
+	recordsInBlock int
+
+	writeOffset  uint64
+	blockOffsets []uint64
+}
+
+func NewCompressedMmapWriter(filename string, codec Codec) (*CompressedMmapWriter, error) {
+	file, err := os.OpenFile(filename, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create compressed file: %w", err)
+	}
+
+	return &CompressedMmapWriter{
+		file:  file,
+		codec: codec,
+	}, nil
+}
+
+func (w *CompressedMmapWriter) WriteRecord(data []byte) error {
+	if len(data) != RecordSize {
+		return fmt.Errorf("data size mismatch: expected %d bytes, got %d", RecordSize, len(data))
+	}
+
+	w.buf = append(w.buf, data...)
+	w.recordsInBlock++
+
+	if w.recordsInBlock == compressedBlockRecords {
+		if err := w.flushBlock(); err != nil {
+			return fmt.Errorf("failed to flush block: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func (w *CompressedMmapWriter) Flush() error {
+	if w.recordsInBlock == 0 {
+		return nil
+	}
+	return w.flushBlock()
+}
+
+func (w *CompressedMmapWriter) flushBlock() error {
+	compressed, err := compress(w.codec, w.buf)
+	if err != nil {
+		return fmt.Errorf("failed to compress block: %w", err)
+	}
+
+	header := make([]byte, compressedBlockHeader)
+	binary.LittleEndian.PutUint32(header[0:4], uint32(len(w.buf)))
+	header[4] = byte(w.codec)
+	binary.LittleEndian.PutUint32(header[5:9], uint32(len(compressed)))
+
+	w.blockOffsets = append(w.blockOffsets, w.writeOffset)
+
+	n1, err := w.file.Write(header)
+	if err != nil {
+		return fmt.Errorf("failed to write block header: %w", err)
+	}
+	n2, err := w.file.Write(compressed)
+	if err != nil {
+		return fmt.Errorf("failed to write block payload: %w", err)
+	}
+
+	w.writeOffset += uint64(n1 + n2)
+	w.buf = w.buf[:0]
+	w.recordsInBlock = 0
+
+	return nil
+}
+
+func (w *CompressedMmapWriter) Close() error {
+	if err := w.Flush(); err != nil {
+		return fmt.Errorf("failed to flush final block: %w", err)
+	}
+
+	trailer := make([]byte, 4+8*len(w.blockOffsets))
+	binary.LittleEndian.PutUint32(trailer[0:4], uint32(len(w.blockOffsets)))
+	for i, offset := range w.blockOffsets {
+		binary.LittleEndian.PutUint64(trailer[4+8*i:12+8*i], offset)
+	}
+
+	if _, err := w.file.Write(trailer); err != nil {
+		return fmt.Errorf("failed to write trailer: %w", err)
+	}
+
+	footer := make([]byte, 4)
+	binary.LittleEndian.PutUint32(footer, uint32(len(trailer)))
+	if _, err := w.file.Write(footer); err != nil {
+		return fmt.Errorf("failed to write trailer length footer: %w", err)
+	}
+
+	return w.file.Close()
+}
+
+type CompressedMmapReader struct {
+	file         *os.File
+	blockOffsets []uint64
+
+	cachedBlock   int
+	cachedRecords [][]byte
+}
+
+func NewCompressedMmapReader(filename string) (*CompressedMmapReader, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open compressed file: %w", err)
+	}
+
+	stat, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, fmt.Errorf("failed to stat compressed file: %w", err)
+	}
+
+	if stat.Size() < 4 {
+		file.Close()
+		return nil, fmt.Errorf("compressed file too small to contain a trailer")
+	}
+
+	footer := make([]byte, 4)
+	if _, err := file.ReadAt(footer, stat.Size()-4); err != nil {
+		file.Close()
+		return nil, fmt.Errorf("failed to read trailer length footer: %w", err)
+	}
+	trailerLen := binary.LittleEndian.Uint32(footer)
+
+	trailer := make([]byte, trailerLen)
+	if _, err := file.ReadAt(trailer, stat.Size()-4-int64(trailerLen)); err != nil {
+		file.Close()
+		return nil, fmt.Errorf("failed to read trailer: %w", err)
+	}
+
+	blockCount := binary.LittleEndian.Uint32(trailer[0:4])
+	offsets := make([]uint64, blockCount)
+	for i := range offsets {
+		offsets[i] = binary.LittleEndian.Uint64(trailer[4+8*i : 12+8*i])
+	}
+
+	return &CompressedMmapReader{
+		file:         file,
+		blockOffsets: offsets,
+		cachedBlock:  -1,
+	}, nil
+}
+
+func (r *CompressedMmapReader) ReadRecord(index int, buf []byte) ([]byte, error) {
+	if index < 0 {
+		return nil, fmt.Errorf("index %d out of range [0, %d)", index, len(r.blockOffsets)*compressedBlockRecords)
+	}
+
+	blockIndex := index / compressedBlockRecords
+	recordInBlock := index % compressedBlockRecords
+
+	if blockIndex >= len(r.blockOffsets) {
+		return nil, fmt.Errorf("index %d maps to out-of-range block %d", index, blockIndex)
+	}
+
+	if blockIndex != r.cachedBlock {
+		records, err := r.readBlock(blockIndex)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read block %d: %w", blockIndex, err)
+		}
+		r.cachedBlock = blockIndex
+		r.cachedRecords = records
+	}
+
+	if recordInBlock >= len(r.cachedRecords) {
+		return nil, fmt.Errorf("index %d out of range for block %d", index, blockIndex)
+	}
+
+	return r.cachedRecords[recordInBlock], nil
+}
+
+func (r *CompressedMmapReader) readBlock(blockIndex int) ([][]byte, error) {
+	offset := int64(r.blockOffsets[blockIndex])
+
+	header := make([]byte, compressedBlockHeader)
+	if _, err := r.file.ReadAt(header, offset); err != nil {
+		return nil, err
+	}
+
+	uncompressedLen := int(binary.LittleEndian.Uint32(header[0:4]))
+	codec := Codec(header[4])
+	compressedLen := int(binary.LittleEndian.Uint32(header[5:9]))
+
+	compressed := make([]byte, compressedLen)
+	if _, err := r.file.ReadAt(compressed, offset+compressedBlockHeader); err != nil && err != io.EOF {
+		return nil, err
+	}
+
+	raw, err := decompress(codec, compressed, uncompressedLen)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decompress block: %w", err)
+	}
+
+	recordCount := len(raw) / RecordSize
+	records := make([][]byte, recordCount)
+	for i := 0; i < recordCount; i++ {
+		records[i] = raw[i*RecordSize : (i+1)*RecordSize]
+	}
+
+	return records, nil
+}
+
+func (r *CompressedMmapReader) Close() error {
+	return r.file.Close()
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"fmt"
+	"math/rand"
+	"path/filepath"
+	"testing"
+)
+
+// TestCompressedMmapRoundTrip verifies that records survive a write/read
+// round trip across a block boundary, for each supported codec.
+func TestCompressedMmapRoundTrip(t *testing.T) {
+	for _, codec := range []Codec{CodecSnappy, CodecLZ4} {
+		codec := codec
+		t.Run(fmt.Sprintf("codec=%d", codec), func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "blocks.cmp")
+
+			writer, err := NewCompressedMmapWriter(path, codec)
+			if err != nil {
+				t.Fatalf("failed to create writer: %v", err)
+			}
+
+			rng := rand.New(rand.NewSource(99))
+			const n = compressedBlockRecords + 5 // spans two blocks
+			records := make([][]byte, n)
+
+			for i := 0; i < n; i++ {
+				data := make([]byte, RecordSize)
+				rng.Read(data)
+				records[i] = data
+				if err := writer.WriteRecord(data); err != nil {
+					t.Fatalf("failed to write record %d: %v", i, err)
+				}
+			}
+
+			if err := writer.Close(); err != nil {
+				t.Fatalf("failed to close writer: %v", err)
+			}
+
+			reader, err := NewCompressedMmapReader(path)
+			if err != nil {
+				t.Fatalf("failed to open reader: %v", err)
+			}
+			defer reader.Close()
+
+			for i, want := range records {
+				got, err := reader.ReadRecord(i, nil)
+				if err != nil {
+					t.Fatalf("failed to read record %d: %v", i, err)
+				}
+				for j := range want {
+					if got[j] != want[j] {
+						t.Fatalf("record %d mismatch at byte %d: got %d, want %d", i, j, got[j], want[j])
+					}
+				}
+			}
+		})
+	}
+}
+
+// TestCompressedMmapReaderNegativeIndex verifies that a negative index is
+// rejected up front instead of being truncated toward zero and panicking on
+// the cached-records slice.
+func TestCompressedMmapReaderNegativeIndex(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "blocks.cmp")
+
+	writer, err := NewCompressedMmapWriter(path, CodecSnappy)
+	if err != nil {
+		t.Fatalf("failed to create writer: %v", err)
+	}
+	if err := writer.WriteRecord(make([]byte, RecordSize)); err != nil {
+		t.Fatalf("failed to write record: %v", err)
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatalf("failed to close writer: %v", err)
+	}
+
+	reader, err := NewCompressedMmapReader(path)
+	if err != nil {
+		t.Fatalf("failed to open reader: %v", err)
+	}
+	defer reader.Close()
+
+	if _, err := reader.ReadRecord(-1, nil); err == nil {
+		t.Fatal("expected error reading negative index")
+	}
+}
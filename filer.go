@@ -0,0 +1,343 @@
+package main
+
+import (
+	"fmt"
+	"os"
+)
+
+const filerPageSize = 4096
+
+// Filer is the low-level storage abstraction underneath Reader/Writer.
+// MmapReader and MmapWriter both satisfy it directly (backed by a shared or
+// private mmap over the whole file), and FilerRecordStore adapts any other
+// Filer - FileFiler, MemFiler, or a commit/rollback OverlayFiler - into a
+// Reader/Writer as well.
+type Filer interface {
+	ReadAt(p []byte, off int64) (int, error)
+	WriteAt(p []byte, off int64) (int, error)
+	Truncate(size int64) error
+	Sync() error
+	Size() (int64, error)
+}
+
+var (
+	_ Filer = (*MmapReader)(nil)
+	_ Filer = (*MmapWriter)(nil)
+
+	_ Reader = (*FilerRecordStore)(nil)
+	_ Writer = (*FilerRecordStore)(nil)
+)
+
+// FileFiler backs a Filer with a real os.File, the storage this package has
+// always used.
+type FileFiler struct {
+	file *os.File
+}
+
+func NewFileFiler(filename string) (*FileFiler, error) {
+	file, err := os.OpenFile(filename, os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open file: %w", err)
+	}
+	return &FileFiler{file: file}, nil
+}
+
+func (f *FileFiler) ReadAt(p []byte, off int64) (int, error) {
+	return f.file.ReadAt(p, off)
+}
+
+func (f *FileFiler) WriteAt(p []byte, off int64) (int, error) {
+	return f.file.WriteAt(p, off)
+}
+
+func (f *FileFiler) Truncate(size int64) error {
+	return f.file.Truncate(size)
+}
+
+func (f *FileFiler) Sync() error {
+	return f.file.Sync()
+}
+
+func (f *FileFiler) Size() (int64, error) {
+	stat, err := f.file.Stat()
+	if err != nil {
+		return 0, fmt.Errorf("failed to stat file: %w", err)
+	}
+	return stat.Size(), nil
+}
+
+func (f *FileFiler) Close() error {
+	return f.file.Close()
+}
+
+// MemFiler is a pure in-memory Filer backed by fixed-size pages, intended
+// for tests that want Filer semantics without touching disk.
+type MemFiler struct {
+	pages map[int64]*[filerPageSize]byte
+	dirty map[int64]bool
+	size  int64
+}
+
+func NewMemFiler() *MemFiler {
+	return &MemFiler{
+		pages: make(map[int64]*[filerPageSize]byte),
+		dirty: make(map[int64]bool),
+	}
+}
+
+func (f *MemFiler) pageFor(pageIndex int64) *[filerPageSize]byte {
+	page, ok := f.pages[pageIndex]
+	if !ok {
+		page = &[filerPageSize]byte{}
+		f.pages[pageIndex] = page
+	}
+	return page
+}
+
+func (f *MemFiler) ReadAt(p []byte, off int64) (int, error) {
+	n := 0
+	for n < len(p) {
+		pos := off + int64(n)
+		pageIndex := pos / filerPageSize
+		pageOffset := pos % filerPageSize
+
+		page := f.pages[pageIndex]
+		toCopy := filerPageSize - int(pageOffset)
+		if toCopy > len(p)-n {
+			toCopy = len(p) - n
+		}
+
+		if page != nil {
+			copy(p[n:n+toCopy], page[pageOffset:int(pageOffset)+toCopy])
+		}
+		n += toCopy
+	}
+	return n, nil
+}
+
+func (f *MemFiler) WriteAt(p []byte, off int64) (int, error) {
+	n := 0
+	for n < len(p) {
+		pos := off + int64(n)
+		pageIndex := pos / filerPageSize
+		pageOffset := pos % filerPageSize
+
+		page := f.pageFor(pageIndex)
+		toCopy := filerPageSize - int(pageOffset)
+		if toCopy > len(p)-n {
+			toCopy = len(p) - n
+		}
+
+		copy(page[pageOffset:int(pageOffset)+toCopy], p[n:n+toCopy])
+		f.dirty[pageIndex] = true
+		n += toCopy
+	}
+
+	if end := off + int64(n); end > f.size {
+		f.size = end
+	}
+	return n, nil
+}
+
+func (f *MemFiler) Truncate(size int64) error {
+	f.size = size
+	return nil
+}
+
+func (f *MemFiler) Sync() error {
+	return nil
+}
+
+func (f *MemFiler) Size() (int64, error) {
+	return f.size, nil
+}
+
+// OverlayFiler reads through to a parent Filer but captures writes into its
+// own in-memory page map, giving callers try-the-change, commit-or-rollback
+// semantics without mutating the parent until Commit is called.
+type OverlayFiler struct {
+	parent   Filer
+	overlay  *MemFiler
+	overlays map[int64]bool
+	size     int64
+}
+
+func NewOverlayFiler(parent Filer) (*OverlayFiler, error) {
+	size, err := parent.Size()
+	if err != nil {
+		return nil, fmt.Errorf("failed to size parent filer: %w", err)
+	}
+
+	return &OverlayFiler{
+		parent:   parent,
+		overlay:  NewMemFiler(),
+		overlays: make(map[int64]bool),
+		size:     size,
+	}, nil
+}
+
+func (f *OverlayFiler) ReadAt(p []byte, off int64) (int, error) {
+	n := 0
+	for n < len(p) {
+		pos := off + int64(n)
+		pageIndex := pos / filerPageSize
+		pageOffset := pos % filerPageSize
+
+		toCopy := filerPageSize - int(pageOffset)
+		if toCopy > len(p)-n {
+			toCopy = len(p) - n
+		}
+
+		if f.overlays[pageIndex] {
+			if _, err := f.overlay.ReadAt(p[n:n+toCopy], pos); err != nil {
+				return n, err
+			}
+		} else if _, err := f.parent.ReadAt(p[n:n+toCopy], pos); err != nil {
+			return n, err
+		}
+
+		n += toCopy
+	}
+	return n, nil
+}
+
+func (f *OverlayFiler) WriteAt(p []byte, off int64) (int, error) {
+	parentSize, err := f.parent.Size()
+	if err != nil {
+		return 0, fmt.Errorf("failed to size parent filer: %w", err)
+	}
+
+	// Copy each page in from the parent before its first write, so the
+	// bytes of the page that this write leaves alone still read as the
+	// parent's and are written back unchanged on Commit.
+	pageIndex := off / filerPageSize
+	lastPageIndex := (off + int64(len(p)) - 1) / filerPageSize
+	for pi := pageIndex; pi <= lastPageIndex; pi++ {
+		start, end := pi*filerPageSize, (pi+1)*filerPageSize
+		if end > parentSize {
+			end = parentSize
+		}
+		if !f.overlays[pi] && start < end {
+			if _, err := f.parent.ReadAt(f.overlay.pageFor(pi)[:end-start], start); err != nil {
+				return 0, fmt.Errorf("failed to copy parent page %d: %w", pi, err)
+			}
+		}
+	}
+
+	n, err := f.overlay.WriteAt(p, off)
+	if err != nil {
+		return n, err
+	}
+
+	for pi := pageIndex; pi <= lastPageIndex; pi++ {
+		f.overlays[pi] = true
+	}
+
+	if end := off + int64(n); end > f.size {
+		f.size = end
+	}
+	return n, nil
+}
+
+func (f *OverlayFiler) Truncate(size int64) error {
+	f.size = size
+	return f.overlay.Truncate(size)
+}
+
+func (f *OverlayFiler) Sync() error {
+	return nil
+}
+
+func (f *OverlayFiler) Size() (int64, error) {
+	return f.size, nil
+}
+
+// Commit writes every overlaid page back to the parent filer and clears the
+// overlay, so subsequent reads go straight through to the parent again.
+func (f *OverlayFiler) Commit() error {
+	for pageIndex := range f.overlays {
+		page := f.overlay.pageFor(pageIndex)
+		if _, err := f.parent.WriteAt(page[:], pageIndex*filerPageSize); err != nil {
+			return fmt.Errorf("failed to commit page %d: %w", pageIndex, err)
+		}
+	}
+	if err := f.parent.Truncate(f.size); err != nil {
+		return fmt.Errorf("failed to commit size: %w", err)
+	}
+
+	f.overlays = make(map[int64]bool)
+	f.overlay = NewMemFiler()
+	return nil
+}
+
+// Rollback discards every write captured since the overlay was created (or
+// last committed), reverting Size and reads to the parent's state.
+func (f *OverlayFiler) Rollback() error {
+	parentSize, err := f.parent.Size()
+	if err != nil {
+		return fmt.Errorf("failed to size parent filer: %w", err)
+	}
+
+	f.overlays = make(map[int64]bool)
+	f.overlay = NewMemFiler()
+	f.size = parentSize
+	return nil
+}
+
+// FilerRecordStore adapts any Filer into a Reader/Writer, so record-level
+// code can run over a FileFiler, a MemFiler, or an OverlayFiler the same way
+// it runs over an MmapReader/MmapWriter. Backing it with an OverlayFiler
+// gives callers try-the-change, Commit-or-Rollback semantics for record
+// updates without touching the underlying file until Commit is called.
+type FilerRecordStore struct {
+	filer Filer
+}
+
+func NewFilerRecordStore(filer Filer) *FilerRecordStore {
+	return &FilerRecordStore{filer: filer}
+}
+
+func (s *FilerRecordStore) ReadRecord(index int, buf []byte) ([]byte, error) {
+	if index < 0 {
+		return nil, fmt.Errorf("index %d out of range", index)
+	}
+	if len(buf) < RecordSize {
+		buf = make([]byte, RecordSize)
+	}
+
+	n, err := s.filer.ReadAt(buf[:RecordSize], int64(index)*RecordSize)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read record %d: %w", index, err)
+	}
+	if n != RecordSize {
+		return nil, fmt.Errorf("partial read: expected %d bytes, got %d", RecordSize, n)
+	}
+
+	return buf[:RecordSize], nil
+}
+
+func (s *FilerRecordStore) WriteRecord(index int, data []byte) error {
+	if index < 0 {
+		return fmt.Errorf("index %d out of range", index)
+	}
+	if len(data) != RecordSize {
+		return fmt.Errorf("data size mismatch: expected %d bytes, got %d", RecordSize, len(data))
+	}
+
+	n, err := s.filer.WriteAt(data, int64(index)*RecordSize)
+	if err != nil {
+		return fmt.Errorf("failed to write record %d: %w", index, err)
+	}
+	if n != RecordSize {
+		return fmt.Errorf("partial write: expected %d bytes, wrote %d", RecordSize, n)
+	}
+
+	return nil
+}
+
+func (s *FilerRecordStore) Close() error {
+	if closer, ok := s.filer.(interface{ Close() error }); ok {
+		return closer.Close()
+	}
+	return nil
+}
@@ -0,0 +1,157 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+)
+
+// TestMemFilerReadWrite verifies basic cross-page read/write correctness for
+// the in-memory Filer.
+func TestMemFilerReadWrite(t *testing.T) {
+	f := NewMemFiler()
+
+	data := bytes.Repeat([]byte{0xCD}, filerPageSize+100)
+	if _, err := f.WriteAt(data, 50); err != nil {
+		t.Fatalf("failed to write: %v", err)
+	}
+
+	got := make([]byte, len(data))
+	if _, err := f.ReadAt(got, 50); err != nil {
+		t.Fatalf("failed to read: %v", err)
+	}
+
+	if !bytes.Equal(got, data) {
+		t.Fatal("read data does not match written data")
+	}
+}
+
+// TestOverlayFilerIsolation verifies that writes to an OverlayFiler are
+// invisible to the parent until Commit, and that Rollback discards them.
+func TestOverlayFilerIsolation(t *testing.T) {
+	parent := NewMemFiler()
+	original := bytes.Repeat([]byte{0xAA}, filerPageSize)
+	if _, err := parent.WriteAt(original, 0); err != nil {
+		t.Fatalf("failed to seed parent: %v", err)
+	}
+
+	overlay, err := NewOverlayFiler(parent)
+	if err != nil {
+		t.Fatalf("failed to create overlay: %v", err)
+	}
+
+	modified := bytes.Repeat([]byte{0xBB}, filerPageSize)
+	if _, err := overlay.WriteAt(modified, 0); err != nil {
+		t.Fatalf("failed to write to overlay: %v", err)
+	}
+
+	// Parent must be untouched.
+	parentCopy := make([]byte, filerPageSize)
+	if _, err := parent.ReadAt(parentCopy, 0); err != nil {
+		t.Fatalf("failed to read parent: %v", err)
+	}
+	if !bytes.Equal(parentCopy, original) {
+		t.Fatal("parent filer was mutated before commit")
+	}
+
+	// Overlay must see the new data.
+	overlayCopy := make([]byte, filerPageSize)
+	if _, err := overlay.ReadAt(overlayCopy, 0); err != nil {
+		t.Fatalf("failed to read overlay: %v", err)
+	}
+	if !bytes.Equal(overlayCopy, modified) {
+		t.Fatal("overlay did not reflect its own write")
+	}
+
+	if err := overlay.Rollback(); err != nil {
+		t.Fatalf("failed to rollback: %v", err)
+	}
+	rolledBack := make([]byte, filerPageSize)
+	if _, err := overlay.ReadAt(rolledBack, 0); err != nil {
+		t.Fatalf("failed to read overlay after rollback: %v", err)
+	}
+	if !bytes.Equal(rolledBack, original) {
+		t.Fatal("rollback did not revert overlay reads to parent state")
+	}
+
+	// Now commit a change and verify it lands on the parent.
+	if _, err := overlay.WriteAt(modified, 0); err != nil {
+		t.Fatalf("failed to write to overlay: %v", err)
+	}
+	if err := overlay.Commit(); err != nil {
+		t.Fatalf("failed to commit: %v", err)
+	}
+	if _, err := parent.ReadAt(parentCopy, 0); err != nil {
+		t.Fatalf("failed to read parent after commit: %v", err)
+	}
+	if !bytes.Equal(parentCopy, modified) {
+		t.Fatal("commit did not propagate overlay writes to parent")
+	}
+}
+
+// TestFilerRecordStoreTransactional verifies that a FilerRecordStore backed
+// by an OverlayFiler gives record updates try/commit/rollback semantics: a
+// second store reading straight from the parent Filer sees nothing until
+// Commit is called, and a Rollback discards the attempted update entirely.
+func TestFilerRecordStoreTransactional(t *testing.T) {
+	parent := NewMemFiler()
+	if err := parent.Truncate(RecordSize); err != nil {
+		t.Fatalf("failed to size parent: %v", err)
+	}
+
+	original := make([]byte, RecordSize)
+	for i := range original {
+		original[i] = 0x11
+	}
+	parentStore := NewFilerRecordStore(parent)
+	if err := parentStore.WriteRecord(0, original); err != nil {
+		t.Fatalf("failed to seed parent record: %v", err)
+	}
+
+	overlay, err := NewOverlayFiler(parent)
+	if err != nil {
+		t.Fatalf("failed to create overlay: %v", err)
+	}
+	txStore := NewFilerRecordStore(overlay)
+
+	updated := make([]byte, RecordSize)
+	for i := range updated {
+		updated[i] = 0x22
+	}
+	if err := txStore.WriteRecord(0, updated); err != nil {
+		t.Fatalf("failed to write record through overlay store: %v", err)
+	}
+
+	got, err := parentStore.ReadRecord(0, nil)
+	if err != nil {
+		t.Fatalf("failed to read parent record: %v", err)
+	}
+	if !bytes.Equal(got, original) {
+		t.Fatal("parent record changed before commit")
+	}
+
+	if err := overlay.Rollback(); err != nil {
+		t.Fatalf("failed to rollback: %v", err)
+	}
+	got, err = txStore.ReadRecord(0, nil)
+	if err != nil {
+		t.Fatalf("failed to read record after rollback: %v", err)
+	}
+	if !bytes.Equal(got, original) {
+		t.Fatal("rollback did not revert the record to the parent's value")
+	}
+
+	if err := txStore.WriteRecord(0, updated); err != nil {
+		t.Fatalf("failed to write record through overlay store: %v", err)
+	}
+	if err := overlay.Commit(); err != nil {
+		t.Fatalf("failed to commit: %v", err)
+	}
+
+	got, err = parentStore.ReadRecord(0, nil)
+	if err != nil {
+		t.Fatalf("failed to read parent record after commit: %v", err)
+	}
+	if !bytes.Equal(got, updated) {
+		t.Fatal("commit did not propagate the record update to the parent")
+	}
+}
@@ -0,0 +1,172 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"sync"
+
+	"golang.org/x/sys/unix"
+)
+
+const mmapAppenderMinCapacity = 16
+
+// MmapAppender is the growable counterpart to MmapWriter: MmapWriter keeps
+// its existing fixed-RecordCount contract (many callers, including the
+// benchmarks in mmap_reader_test.go, rely on that file's size never moving
+// under them), so growth/append support lives in this separate type rather
+// than bolted onto MmapWriter itself.
+type MmapAppender struct {
+	mu       sync.RWMutex
+	file     *os.File
+	data     []byte
+	capacity int
+	length   int
+}
+
+func NewMmapAppender(filename string) (*MmapAppender, error) {
+	file, err := os.OpenFile(filename, os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open file for appending: %w", err)
+	}
+
+	stat, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, fmt.Errorf("failed to stat file: %w", err)
+	}
+
+	capacity := int(stat.Size()) / RecordSize
+	length := capacity
+
+	if capacity == 0 {
+		capacity = mmapAppenderMinCapacity
+		if err := unix.Ftruncate(int(file.Fd()), int64(capacity*RecordSize)); err != nil {
+			file.Close()
+			return nil, fmt.Errorf("failed to allocate initial capacity: %w", err)
+		}
+	}
+
+	data, err := unix.Mmap(int(file.Fd()), 0, capacity*RecordSize, unix.PROT_READ|unix.PROT_WRITE, unix.MAP_SHARED)
+	if err != nil {
+		file.Close()
+		return nil, fmt.Errorf("failed to mmap file for appending: %w", err)
+	}
+
+	return &MmapAppender{
+		file:     file,
+		data:     data,
+		capacity: capacity,
+		length:   length,
+	}, nil
+}
+
+func (a *MmapAppender) Len() int {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return a.length
+}
+
+func (a *MmapAppender) Append(data []byte) (int, error) {
+	if len(data) != RecordSize {
+		return 0, fmt.Errorf("data size mismatch: expected %d bytes, got %d", RecordSize, len(data))
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.length >= a.capacity {
+		if err := a.grow(a.capacity * 2); err != nil {
+			return 0, fmt.Errorf("failed to grow before append: %w", err)
+		}
+	}
+
+	index := a.length
+	offset := index * RecordSize
+	copy(a.data[offset:offset+RecordSize], data)
+	a.length++
+
+	return index, nil
+}
+
+func (a *MmapAppender) Grow(newRecordCount int) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.grow(newRecordCount)
+}
+
+// grow must be called with a.mu held for writing.
+func (a *MmapAppender) grow(newRecordCount int) error {
+	if newRecordCount <= a.capacity {
+		return nil
+	}
+
+	newSize := newRecordCount * RecordSize
+	if err := unix.Ftruncate(int(a.file.Fd()), int64(newSize)); err != nil {
+		return fmt.Errorf("failed to extend file: %w", err)
+	}
+
+	newData, err := remapGrown(int(a.file.Fd()), a.data, newSize)
+	if err != nil {
+		a.data = nil
+		return fmt.Errorf("failed to remap file: %w", err)
+	}
+
+	a.data = newData
+	a.capacity = newRecordCount
+	return nil
+}
+
+// ReadRecord copies the record into buf (allocating one if buf is too small)
+// rather than returning a slice of the mapping: Append and Grow can remap
+// the backing memory to a new address at any time, which would leave an
+// aliased slice pointing at unmapped memory.
+func (a *MmapAppender) ReadRecord(index int, buf []byte) ([]byte, error) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	if index < 0 || index >= a.length {
+		return nil, fmt.Errorf("index %d out of range [0, %d)", index, a.length)
+	}
+
+	if len(buf) < RecordSize {
+		buf = make([]byte, RecordSize)
+	}
+
+	offset := index * RecordSize
+	copy(buf[:RecordSize], a.data[offset:offset+RecordSize])
+	return buf[:RecordSize], nil
+}
+
+func (a *MmapAppender) WriteRecord(index int, data []byte) error {
+	if len(data) != RecordSize {
+		return fmt.Errorf("data size mismatch: expected %d bytes, got %d", RecordSize, len(data))
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if index < 0 || index >= a.length {
+		return fmt.Errorf("index %d out of range [0, %d)", index, a.length)
+	}
+
+	offset := index * RecordSize
+	copy(a.data[offset:offset+RecordSize], data)
+	return nil
+}
+
+func (a *MmapAppender) Close() error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	var err1, err2 error
+	if a.data != nil {
+		err1 = unix.Munmap(a.data)
+	}
+	if a.file != nil {
+		err2 = a.file.Close()
+	}
+	if err1 != nil {
+		return err1
+	}
+	return err2
+}
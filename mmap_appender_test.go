@@ -0,0 +1,111 @@
+package main
+
+import (
+	"math/rand"
+	"path/filepath"
+	"testing"
+)
+
+// TestMmapAppenderGrowth verifies that Append transparently grows the backing
+// file and remaps it once the current capacity is exhausted, without losing
+// previously appended records.
+func TestMmapAppenderGrowth(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "appender.bin")
+
+	appender, err := NewMmapAppender(path)
+	if err != nil {
+		t.Fatalf("failed to create appender: %v", err)
+	}
+	defer appender.Close()
+
+	rng := rand.New(rand.NewSource(7))
+	const n = mmapAppenderMinCapacity*2 + 5 // force at least two grows
+	records := make([][]byte, n)
+
+	for i := 0; i < n; i++ {
+		data := make([]byte, RecordSize)
+		rng.Read(data)
+		records[i] = data
+
+		index, err := appender.Append(data)
+		if err != nil {
+			t.Fatalf("failed to append record %d: %v", i, err)
+		}
+		if index != i {
+			t.Fatalf("expected index %d, got %d", i, index)
+		}
+	}
+
+	if got := appender.Len(); got != n {
+		t.Fatalf("expected length %d, got %d", n, got)
+	}
+
+	for i, want := range records {
+		got, err := appender.ReadRecord(i, nil)
+		if err != nil {
+			t.Fatalf("failed to read record %d: %v", i, err)
+		}
+		for j := range want {
+			if got[j] != want[j] {
+				t.Fatalf("record %d mismatch at byte %d: got %d, want %d", i, j, got[j], want[j])
+			}
+		}
+	}
+}
+
+// TestMmapAppenderReadSurvivesRemap verifies that a slice returned by
+// ReadRecord stays valid (and unchanged) even after later Append calls force
+// the backing mapping to move, since ReadRecord must copy rather than alias.
+func TestMmapAppenderReadSurvivesRemap(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "appender.bin")
+
+	appender, err := NewMmapAppender(path)
+	if err != nil {
+		t.Fatalf("failed to create appender: %v", err)
+	}
+	defer appender.Close()
+
+	data := make([]byte, RecordSize)
+	rand.New(rand.NewSource(3)).Read(data)
+	if _, err := appender.Append(data); err != nil {
+		t.Fatalf("failed to append record: %v", err)
+	}
+
+	got, err := appender.ReadRecord(0, nil)
+	if err != nil {
+		t.Fatalf("failed to read record: %v", err)
+	}
+	want := append([]byte{}, got...)
+
+	// Force several remaps past the record we read.
+	filler := make([]byte, RecordSize)
+	for i := 0; i < mmapAppenderMinCapacity*4; i++ {
+		if _, err := appender.Append(filler); err != nil {
+			t.Fatalf("failed to append filler record %d: %v", i, err)
+		}
+	}
+
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("previously read record changed after remap at byte %d: got %d, want %d", i, got[i], want[i])
+		}
+	}
+}
+
+// TestMmapAppenderOutOfRange verifies that reads and writes past Len() are rejected.
+func TestMmapAppenderOutOfRange(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "appender.bin")
+
+	appender, err := NewMmapAppender(path)
+	if err != nil {
+		t.Fatalf("failed to create appender: %v", err)
+	}
+	defer appender.Close()
+
+	if _, err := appender.ReadRecord(0, nil); err == nil {
+		t.Fatal("expected error reading from empty appender")
+	}
+	if err := appender.WriteRecord(0, make([]byte, RecordSize)); err == nil {
+		t.Fatal("expected error writing to empty appender")
+	}
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"fmt"
+
+	"golang.org/x/sys/unix"
+)
+
+type AccessPattern int
+
+const (
+	AccessPatternNormal AccessPattern = iota
+	AccessPatternRandom
+	AccessPatternSequential
+)
+
+type MmapOptions struct {
+	Populate      bool
+	HugePages     bool
+	AccessPattern AccessPattern
+	Mlock         bool
+}
+
+func (o MmapOptions) mapFlags() int {
+	var flags int
+	if o.Populate {
+		flags |= populateMapFlag()
+	}
+	return flags
+}
+
+func (o MmapOptions) apply(data []byte) error {
+	switch o.AccessPattern {
+	case AccessPatternRandom:
+		if err := unix.Madvise(data, unix.MADV_RANDOM); err != nil {
+			return fmt.Errorf("failed to madvise MADV_RANDOM: %w", err)
+		}
+	case AccessPatternSequential:
+		if err := unix.Madvise(data, unix.MADV_SEQUENTIAL); err != nil {
+			return fmt.Errorf("failed to madvise MADV_SEQUENTIAL: %w", err)
+		}
+	}
+
+	if o.HugePages {
+		if err := adviseHugePages(data); err != nil {
+			return fmt.Errorf("failed to advise huge pages: %w", err)
+		}
+	}
+
+	if o.Mlock {
+		if err := unix.Mlock(data); err != nil {
+			return fmt.Errorf("failed to mlock mapping: %w", err)
+		}
+	}
+
+	return nil
+}
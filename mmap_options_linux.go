@@ -0,0 +1,13 @@
+//go:build linux
+
+package main
+
+import "golang.org/x/sys/unix"
+
+func populateMapFlag() int {
+	return unix.MAP_POPULATE
+}
+
+func adviseHugePages(data []byte) error {
+	return unix.Madvise(data, unix.MADV_HUGEPAGE)
+}
@@ -0,0 +1,14 @@
+//go:build !linux
+
+package main
+
+// populateMapFlag and adviseHugePages are no-ops outside Linux: MAP_POPULATE
+// and MADV_HUGEPAGE (transparent huge pages) are Linux-specific extensions
+// with no equivalent in the BSD/Darwin mmap/madvise API.
+func populateMapFlag() int {
+	return 0
+}
+
+func adviseHugePages(data []byte) error {
+	return nil
+}
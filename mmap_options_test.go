@@ -0,0 +1,45 @@
+package main
+
+import (
+	"math/rand"
+	"testing"
+)
+
+// BenchmarkAccessPatternHints measures the effect of MADV_RANDOM, MADV_SEQUENTIAL,
+// and MAP_POPULATE against the unhinted baseline, complementing the cold/warm
+// page behavior already probed by BenchmarkColdVsWarmPages.
+func BenchmarkAccessPatternHints(b *testing.B) {
+	cases := []struct {
+		name string
+		opts MmapOptions
+	}{
+		{"Baseline", MmapOptions{}},
+		{"Populate", MmapOptions{Populate: true}},
+		{"AdviseRandom", MmapOptions{AccessPattern: AccessPatternRandom}},
+		{"AdviseSequential", MmapOptions{AccessPattern: AccessPatternSequential}},
+	}
+
+	for _, c := range cases {
+		b.Run(c.name, func(b *testing.B) {
+			reader, err := NewMmapReaderWithOptions(testFile, c.opts)
+			if err != nil {
+				b.Fatalf("failed to create mmap reader: %v", err)
+			}
+			defer reader.Close()
+
+			rng := rand.New(rand.NewSource(42))
+			indices := make([]int, b.N)
+			for i := 0; i < b.N; i++ {
+				indices[i] = rng.Intn(RecordCount)
+			}
+
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				_, err := reader.ReadRecord(indices[i], nil)
+				if err != nil {
+					b.Fatalf("failed to read record %d: %v", indices[i], err)
+				}
+			}
+		})
+	}
+}
@@ -109,6 +109,10 @@ type MmapReader struct {
 }
 
 func NewMmapReader(filename string) (*MmapReader, error) {
+	return NewMmapReaderWithOptions(filename, MmapOptions{})
+}
+
+func NewMmapReaderWithOptions(filename string, opts MmapOptions) (*MmapReader, error) {
 	file, err := os.Open(filename)
 	if err != nil {
 		return nil, fmt.Errorf("failed to open file: %w", err)
@@ -120,12 +124,19 @@ func NewMmapReader(filename string) (*MmapReader, error) {
 		return nil, fmt.Errorf("failed to stat file: %w", err)
 	}
 
-	data, err := unix.Mmap(int(file.Fd()), 0, int(stat.Size()), unix.PROT_READ, unix.MAP_PRIVATE)
+	flags := unix.MAP_PRIVATE | opts.mapFlags()
+	data, err := unix.Mmap(int(file.Fd()), 0, int(stat.Size()), unix.PROT_READ, flags)
 	if err != nil {
 		file.Close()
 		return nil, fmt.Errorf("failed to mmap file: %w", err)
 	}
 
+	if err := opts.apply(data); err != nil {
+		unix.Munmap(data)
+		file.Close()
+		return nil, fmt.Errorf("failed to apply mmap options: %w", err)
+	}
+
 	return &MmapReader{
 		file: file,
 		data: data,
@@ -145,6 +156,31 @@ func (m *MmapReader) ReadRecord(index int, buf []byte) ([]byte, error) {
 	return m.data[offset : offset+RecordSize], nil
 }
 
+// ReadAt, Sync, Truncate and Size make MmapReader a Filer: a read-only one,
+// backed by a private mapping over the whole file rather than a plain fd.
+func (m *MmapReader) ReadAt(p []byte, off int64) (int, error) {
+	if off < 0 || off+int64(len(p)) > int64(len(m.data)) {
+		return 0, fmt.Errorf("read at %d, len %d exceeds mapped region of %d bytes", off, len(p), len(m.data))
+	}
+	return copy(p, m.data[off:off+int64(len(p))]), nil
+}
+
+func (m *MmapReader) WriteAt(p []byte, off int64) (int, error) {
+	return 0, fmt.Errorf("MmapReader is read-only and does not support WriteAt")
+}
+
+func (m *MmapReader) Sync() error {
+	return nil
+}
+
+func (m *MmapReader) Truncate(size int64) error {
+	return fmt.Errorf("MmapReader is read-only and does not support Truncate")
+}
+
+func (m *MmapReader) Size() (int64, error) {
+	return int64(len(m.data)), nil
+}
+
 func (m *MmapReader) Close() error {
 	var err1, err2 error
 	if m.data != nil {
@@ -165,6 +201,10 @@ type MmapWriter struct {
 }
 
 func NewMmapWriter(filename string) (*MmapWriter, error) {
+	return NewMmapWriterWithOptions(filename, MmapOptions{})
+}
+
+func NewMmapWriterWithOptions(filename string, opts MmapOptions) (*MmapWriter, error) {
 	file, err := os.OpenFile(filename, os.O_RDWR, 0644)
 	if err != nil {
 		return nil, fmt.Errorf("failed to open file for writing: %w", err)
@@ -176,18 +216,38 @@ func NewMmapWriter(filename string) (*MmapWriter, error) {
 		return nil, fmt.Errorf("failed to stat file: %w", err)
 	}
 
-	data, err := unix.Mmap(int(file.Fd()), 0, int(stat.Size()), unix.PROT_READ|unix.PROT_WRITE, unix.MAP_SHARED)
+	flags := unix.MAP_SHARED | opts.mapFlags()
+	data, err := unix.Mmap(int(file.Fd()), 0, int(stat.Size()), unix.PROT_READ|unix.PROT_WRITE, flags)
 	if err != nil {
 		file.Close()
 		return nil, fmt.Errorf("failed to mmap file for writing: %w", err)
 	}
 
+	if err := opts.apply(data); err != nil {
+		unix.Munmap(data)
+		file.Close()
+		return nil, fmt.Errorf("failed to apply mmap options: %w", err)
+	}
+
 	return &MmapWriter{
 		file: file,
 		data: data,
 	}, nil
 }
 
+func (w *MmapWriter) ReadRecord(index int, buf []byte) ([]byte, error) {
+	if index < 0 || index >= RecordCount {
+		return nil, fmt.Errorf("index %d out of range [0, %d)", index, RecordCount)
+	}
+
+	offset := index * RecordSize
+	if offset+RecordSize > len(w.data) {
+		return nil, fmt.Errorf("record %d would exceed file bounds", index)
+	}
+
+	return w.data[offset : offset+RecordSize], nil
+}
+
 func (w *MmapWriter) WriteRecord(index int, data []byte) error {
 	if index < 0 || index >= RecordCount {
 		return fmt.Errorf("index %d out of range [0, %d)", index, RecordCount)
@@ -220,6 +280,37 @@ func (w *MmapWriter) Close() error {
 	return err2
 }
 
+// ReadAt, WriteAt, Truncate and Size make MmapWriter a Filer: one backed by
+// a shared mmap over the whole file rather than a plain fd.
+func (w *MmapWriter) ReadAt(p []byte, off int64) (int, error) {
+	if off < 0 || off+int64(len(p)) > int64(len(w.data)) {
+		return 0, fmt.Errorf("read at %d, len %d exceeds mapped region of %d bytes", off, len(p), len(w.data))
+	}
+	return copy(p, w.data[off:off+int64(len(p))]), nil
+}
+
+func (w *MmapWriter) WriteAt(p []byte, off int64) (int, error) {
+	if off < 0 || off+int64(len(p)) > int64(len(w.data)) {
+		return 0, fmt.Errorf("write at %d, len %d exceeds mapped region of %d bytes", off, len(p), len(w.data))
+	}
+	return copy(w.data[off:off+int64(len(p))], p), nil
+}
+
+func (w *MmapWriter) Truncate(size int64) error {
+	return fmt.Errorf("MmapWriter does not support Truncate; use MmapAppender.Grow to resize the mapping")
+}
+
+func (w *MmapWriter) Size() (int64, error) {
+	return int64(len(w.data)), nil
+}
+
+func (w *MmapWriter) Sync() error {
+	if w.data == nil {
+		return nil
+	}
+	return unix.Msync(w.data, unix.MS_SYNC)
+}
+
 func (w *MmapWriter) EvictPages() error {
 	if w.data == nil {
 		return nil
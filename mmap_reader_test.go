@@ -331,6 +331,65 @@ func TestWriterConsistency(t *testing.T) {
 	}
 }
 
+// TestMmapWriterAsFiler verifies that MmapWriter satisfies Filer and that a
+// FilerRecordStore built on top of it round-trips records the same way
+// WriteRecord/ReadRecord do directly.
+func TestMmapWriterAsFiler(t *testing.T) {
+	tempFile := "filer_test.bin"
+	defer os.Remove(tempFile)
+
+	srcFile, err := os.Open(testFile)
+	if err != nil {
+		t.Fatalf("failed to open source file: %v", err)
+	}
+	dst, err := os.Create(tempFile)
+	if err != nil {
+		t.Fatalf("failed to create temp file: %v", err)
+	}
+	_, err = io.Copy(dst, srcFile)
+	srcFile.Close()
+	dst.Close()
+	if err != nil {
+		t.Fatalf("failed to copy test file: %v", err)
+	}
+
+	mmapWriter, err := NewMmapWriter(tempFile)
+	if err != nil {
+		t.Fatalf("failed to create mmap writer: %v", err)
+	}
+	defer mmapWriter.Close()
+
+	store := NewFilerRecordStore(mmapWriter)
+
+	data := make([]byte, RecordSize)
+	rng := rand.New(rand.NewSource(55))
+	rng.Read(data)
+
+	if err := store.WriteRecord(42, data); err != nil {
+		t.Fatalf("failed to write record through FilerRecordStore: %v", err)
+	}
+
+	got, err := store.ReadRecord(42, nil)
+	if err != nil {
+		t.Fatalf("failed to read record through FilerRecordStore: %v", err)
+	}
+	for i := range data {
+		if got[i] != data[i] {
+			t.Fatalf("record mismatch at byte %d: got %d, want %d", i, got[i], data[i])
+		}
+	}
+
+	direct, err := mmapWriter.ReadRecord(42, nil)
+	if err != nil {
+		t.Fatalf("failed to read record directly: %v", err)
+	}
+	for i := range data {
+		if direct[i] != data[i] {
+			t.Fatalf("direct ReadRecord mismatch at byte %d: got %d, want %d", i, direct[i], data[i])
+		}
+	}
+}
+
 // BenchmarkRandomWrite measures single random record write performance.
 // Uses deterministic random seed for reproducible results across runs.
 func BenchmarkRandomWrite(b *testing.B) {
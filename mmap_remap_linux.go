@@ -0,0 +1,20 @@
+//go:build linux
+
+package main
+
+import "golang.org/x/sys/unix"
+
+// remapGrown returns a newSize-byte mapping backed by fd, preferring mremap
+// (which can often extend the mapping in place) and falling back to an
+// explicit unmap+mmap if the kernel can't satisfy that.
+func remapGrown(fd int, data []byte, newSize int) ([]byte, error) {
+	newData, err := unix.Mremap(data, newSize, unix.MREMAP_MAYMOVE)
+	if err == nil {
+		return newData, nil
+	}
+
+	if munmapErr := unix.Munmap(data); munmapErr != nil {
+		return nil, munmapErr
+	}
+	return unix.Mmap(fd, 0, newSize, unix.PROT_READ|unix.PROT_WRITE, unix.MAP_SHARED)
+}
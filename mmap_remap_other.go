@@ -0,0 +1,14 @@
+//go:build !linux
+
+package main
+
+import "golang.org/x/sys/unix"
+
+// remapGrown returns a newSize-byte mapping backed by fd. mremap has no
+// equivalent outside Linux, so growth always takes the unmap+mmap path.
+func remapGrown(fd int, data []byte, newSize int) ([]byte, error) {
+	if err := unix.Munmap(data); err != nil {
+		return nil, err
+	}
+	return unix.Mmap(fd, 0, newSize, unix.PROT_READ|unix.PROT_WRITE, unix.MAP_SHARED)
+}
@@ -0,0 +1,170 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"math/rand"
+	"path/filepath"
+	"sync"
+	"testing"
+)
+
+// TestShardedMmapWriterAtomicUpdates verifies that concurrent atomic writes
+// to distinct records land correctly: once the writers finish, ReadRecord
+// returns the last fully-written copy of each record.
+func TestShardedMmapWriterAtomicUpdates(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sharded.bin")
+	const recordCount = 256
+
+	writer, err := NewShardedMmapWriter(path, recordCount, 8)
+	if err != nil {
+		t.Fatalf("failed to create sharded writer: %v", err)
+	}
+	defer writer.Close()
+
+	var wg sync.WaitGroup
+	for i := 0; i < recordCount; i++ {
+		wg.Add(1)
+		go func(index int) {
+			defer wg.Done()
+			rng := rand.New(rand.NewSource(int64(index)))
+			for j := 0; j < 10; j++ {
+				data := make([]byte, RecordSize)
+				rng.Read(data)
+				if err := writer.WriteRecordAtomic(index, data); err != nil {
+					t.Errorf("failed to write record %d: %v", index, err)
+					return
+				}
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	for i := 0; i < recordCount; i++ {
+		// Replay the writer's random stream to get the last copy it wrote.
+		rng := rand.New(rand.NewSource(int64(i)))
+		want := make([]byte, RecordSize)
+		for j := 0; j < 10; j++ {
+			rng.Read(want)
+		}
+
+		got, err := writer.ReadRecord(i, nil)
+		if err != nil {
+			t.Fatalf("failed to read record %d: %v", i, err)
+		}
+		if !bytes.Equal(got, want) {
+			t.Fatalf("record %d does not hold the last copy written to it", i)
+		}
+	}
+}
+
+// TestShardedMmapWriterConcurrentReadWrite races ReadRecord against
+// WriteRecordAtomic on the *same* index, unlike
+// TestShardedMmapWriterAtomicUpdates (which only reads after all writers have
+// finished). It exists to catch ReadRecord running unsynchronized against a
+// concurrent writer; run with -race to confirm no data race is reported.
+func TestShardedMmapWriterConcurrentReadWrite(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sharded_rw.bin")
+	const index = 0
+
+	writer, err := NewShardedMmapWriter(path, 4, 1)
+	if err != nil {
+		t.Fatalf("failed to create sharded writer: %v", err)
+	}
+	defer writer.Close()
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+
+	go func() {
+		defer wg.Done()
+		rng := rand.New(rand.NewSource(11))
+		data := make([]byte, RecordSize)
+		for i := 0; i < 1000; i++ {
+			rng.Read(data)
+			if err := writer.WriteRecordAtomic(index, data); err != nil {
+				t.Errorf("failed to write record: %v", err)
+				return
+			}
+		}
+	}()
+
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 1000; i++ {
+			if _, err := writer.ReadRecord(index, nil); err != nil {
+				t.Errorf("failed to read record: %v", err)
+				return
+			}
+		}
+	}()
+
+	wg.Wait()
+}
+
+// BenchmarkConcurrentWrite compares ShardedMmapWriter's atomic writes against
+// the single-lock-free MmapWriter under increasing goroutine counts.
+func BenchmarkConcurrentWrite(b *testing.B) {
+	goroutineCounts := []int{1, 4, 16, 64}
+
+	for _, n := range goroutineCounts {
+		b.Run(fmt.Sprintf("Sharded_Goroutines%d", n), func(b *testing.B) {
+			path := filepath.Join(b.TempDir(), "sharded_bench.bin")
+			writer, err := NewShardedMmapWriter(path, RecordCount, 32)
+			if err != nil {
+				b.Fatalf("failed to create sharded writer: %v", err)
+			}
+			defer writer.Close()
+
+			b.ResetTimer()
+			var wg sync.WaitGroup
+			perGoroutine := b.N / n
+			if perGoroutine == 0 {
+				perGoroutine = 1
+			}
+			for g := 0; g < n; g++ {
+				wg.Add(1)
+				go func(seed int64) {
+					defer wg.Done()
+					rng := rand.New(rand.NewSource(seed))
+					data := make([]byte, RecordSize)
+					for i := 0; i < perGoroutine; i++ {
+						index := rng.Intn(RecordCount)
+						rng.Read(data)
+						_ = writer.WriteRecordAtomic(index, data)
+					}
+				}(int64(g))
+			}
+			wg.Wait()
+		})
+
+		b.Run(fmt.Sprintf("Mmap_Goroutines%d", n), func(b *testing.B) {
+			writer, err := NewMmapWriter(testFile)
+			if err != nil {
+				b.Fatalf("failed to create mmap writer: %v", err)
+			}
+			defer writer.Close()
+
+			b.ResetTimer()
+			var wg sync.WaitGroup
+			perGoroutine := b.N / n
+			if perGoroutine == 0 {
+				perGoroutine = 1
+			}
+			for g := 0; g < n; g++ {
+				wg.Add(1)
+				go func(seed int64) {
+					defer wg.Done()
+					rng := rand.New(rand.NewSource(seed))
+					data := make([]byte, RecordSize)
+					for i := 0; i < perGoroutine; i++ {
+						index := rng.Intn(RecordCount)
+						rng.Read(data)
+						_ = writer.WriteRecord(index, data)
+					}
+				}(int64(g))
+			}
+			wg.Wait()
+		})
+	}
+}
@@ -0,0 +1,271 @@
+package main
+
+import (
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+const (
+	walMagicByte          = 0xA5
+	walHeaderSize         = 1 + 8 + 4 // magic + index + length
+	walFooterSize         = 4         // crc32
+	walDefaultMaxBytes    = 64 << 20  // 64MB per segment
+	walDefaultMaxEntries  = 100000
+	walSegmentFilePattern = "wal-%020d.log"
+)
+
+type WALOptions struct {
+	MaxSegmentBytes int64
+	MaxEntries      int
+}
+
+type WALWriter struct {
+	mw *MmapWriter
+
+	dir             string
+	segment         *os.File
+	segmentIndex    int64
+	segmentBytes    int64
+	segmentEntries  int
+	maxSegmentBytes int64
+	maxEntries      int
+}
+
+func NewWALWriter(dataFile, walDir string, opts WALOptions) (*WALWriter, error) {
+	if opts.MaxSegmentBytes <= 0 {
+		opts.MaxSegmentBytes = walDefaultMaxBytes
+	}
+	if opts.MaxEntries <= 0 {
+		opts.MaxEntries = walDefaultMaxEntries
+	}
+
+	if err := os.MkdirAll(walDir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create wal dir: %w", err)
+	}
+
+	mw, err := NewMmapWriter(dataFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open data file: %w", err)
+	}
+
+	w := &WALWriter{
+		mw:              mw,
+		dir:             walDir,
+		maxSegmentBytes: opts.MaxSegmentBytes,
+		maxEntries:      opts.MaxEntries,
+	}
+
+	if err := w.replay(); err != nil {
+		mw.Close()
+		return nil, fmt.Errorf("failed to replay wal: %w", err)
+	}
+
+	if err := w.openNewSegment(); err != nil {
+		mw.Close()
+		return nil, fmt.Errorf("failed to open wal segment: %w", err)
+	}
+
+	return w, nil
+}
+
+func (w *WALWriter) segmentPaths() ([]string, error) {
+	entries, err := os.ReadDir(w.dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var paths []string
+	for _, e := range entries {
+		if e.IsDir() {
+			continue
+		}
+		paths = append(paths, filepath.Join(w.dir, e.Name()))
+	}
+	sort.Strings(paths)
+	return paths, nil
+}
+
+func (w *WALWriter) replay() error {
+	paths, err := w.segmentPaths()
+	if err != nil {
+		return err
+	}
+
+	var highestIndex int64 = -1
+	for _, path := range paths {
+		var idx int64
+		if _, err := fmt.Sscanf(filepath.Base(path), walSegmentFilePattern, &idx); err == nil && idx > highestIndex {
+			highestIndex = idx
+		}
+
+		if err := w.replaySegment(path); err != nil {
+			return fmt.Errorf("failed to replay segment %s: %w", path, err)
+		}
+	}
+
+	if highestIndex >= 0 {
+		w.segmentIndex = highestIndex + 1
+	}
+	return nil
+}
+
+func (w *WALWriter) replaySegment(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	header := make([]byte, walHeaderSize)
+	for {
+		if _, err := io.ReadFull(f, header); err != nil {
+			if err == io.EOF || err == io.ErrUnexpectedEOF {
+				return nil
+			}
+			return err
+		}
+
+		if header[0] != walMagicByte {
+			return nil
+		}
+
+		index := int(binary.LittleEndian.Uint64(header[1:9]))
+		length := binary.LittleEndian.Uint32(header[9:13])
+
+		payload := make([]byte, length)
+		if _, err := io.ReadFull(f, payload); err != nil {
+			if err == io.EOF || err == io.ErrUnexpectedEOF {
+				return nil
+			}
+			return err
+		}
+
+		footer := make([]byte, walFooterSize)
+		if _, err := io.ReadFull(f, footer); err != nil {
+			if err == io.EOF || err == io.ErrUnexpectedEOF {
+				return nil
+			}
+			return err
+		}
+
+		sum := crc32.ChecksumIEEE(append(append([]byte{}, header[1:]...), payload...))
+		if binary.LittleEndian.Uint32(footer) != sum {
+			return nil
+		}
+
+		if err := w.mw.WriteRecord(index, payload); err != nil {
+			return fmt.Errorf("failed to apply replayed record %d: %w", index, err)
+		}
+	}
+}
+
+func (w *WALWriter) openNewSegment() error {
+	path := filepath.Join(w.dir, fmt.Sprintf(walSegmentFilePattern, w.segmentIndex))
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+
+	w.segment = f
+	w.segmentBytes = 0
+	w.segmentEntries = 0
+	w.segmentIndex++
+	return nil
+}
+
+func (w *WALWriter) WriteRecord(index int, data []byte) error {
+	if w.segmentEntries >= w.maxEntries || w.segmentBytes >= w.maxSegmentBytes {
+		if err := w.rotateSegment(); err != nil {
+			return fmt.Errorf("failed to rotate wal segment: %w", err)
+		}
+	}
+
+	header := make([]byte, walHeaderSize)
+	header[0] = walMagicByte
+	binary.LittleEndian.PutUint64(header[1:9], uint64(index))
+	binary.LittleEndian.PutUint32(header[9:13], uint32(len(data)))
+
+	sum := crc32.ChecksumIEEE(append(append([]byte{}, header[1:]...), data...))
+	footer := make([]byte, walFooterSize)
+	binary.LittleEndian.PutUint32(footer, sum)
+
+	entry := append(append(header, data...), footer...)
+	n, err := w.segment.Write(entry)
+	if err != nil {
+		return fmt.Errorf("failed to append wal entry: %w", err)
+	}
+	if err := w.segment.Sync(); err != nil {
+		return fmt.Errorf("failed to fsync wal segment: %w", err)
+	}
+
+	w.segmentBytes += int64(n)
+	w.segmentEntries++
+
+	if err := w.mw.WriteRecord(index, data); err != nil {
+		return fmt.Errorf("failed to apply record %d: %w", index, err)
+	}
+
+	return nil
+}
+
+func (w *WALWriter) rotateSegment() error {
+	if w.segment != nil {
+		if err := w.segment.Close(); err != nil {
+			return err
+		}
+	}
+	return w.openNewSegment()
+}
+
+func (w *WALWriter) Sync() error {
+	if err := w.segment.Sync(); err != nil {
+		return fmt.Errorf("failed to fsync wal segment: %w", err)
+	}
+	return nil
+}
+
+func (w *WALWriter) Checkpoint() error {
+	if err := w.mw.Sync(); err != nil {
+		return fmt.Errorf("failed to msync data region: %w", err)
+	}
+
+	paths, err := w.segmentPaths()
+	if err != nil {
+		return fmt.Errorf("failed to list wal segments: %w", err)
+	}
+
+	if err := w.rotateSegment(); err != nil {
+		return fmt.Errorf("failed to rotate wal segment: %w", err)
+	}
+
+	newSegmentPath := filepath.Join(w.dir, fmt.Sprintf(walSegmentFilePattern, w.segmentIndex-1))
+	for _, path := range paths {
+		if path == newSegmentPath {
+			continue
+		}
+		if err := os.Remove(path); err != nil {
+			return fmt.Errorf("failed to remove checkpointed segment %s: %w", path, err)
+		}
+	}
+
+	return nil
+}
+
+func (w *WALWriter) Close() error {
+	var err1, err2 error
+	if w.segment != nil {
+		err1 = w.segment.Close()
+	}
+	if w.mw != nil {
+		err2 = w.mw.Close()
+	}
+	if err1 != nil {
+		return err1
+	}
+	return err2
+}